@@ -0,0 +1,213 @@
+package main
+
+import (
+	"net/url"
+	"sync"
+
+	log "github.com/cihub/seelog"
+)
+
+// LinkType categorises a URL discovered while crawling a page: whether it
+// drives further crawl expansion, or is merely archived alongside the page
+// that referenced it.
+type LinkType int
+
+const (
+	// LinkTypePrimary is an anchor href the crawler may recurse into.
+	LinkTypePrimary LinkType = iota
+	// LinkTypeRelated is an asset (image, script, stylesheet, ...) that is
+	// archived but never itself enqueued for crawling.
+	LinkTypeRelated
+)
+
+func (t LinkType) String() string {
+	switch t {
+	case LinkTypePrimary:
+		return "primary"
+	case LinkTypeRelated:
+		return "related"
+	default:
+		return "unknown"
+	}
+}
+
+// Result is emitted on the Crawler's Results channel once per fetched URL.
+type Result struct {
+	URL    *url.URL
+	Depth  int
+	Parent *url.URL
+	Tag    LinkType
+	Assets []*url.URL
+	Err    error
+}
+
+// Scope decides whether a discovered URL should be enqueued for crawling.
+// Implementations are combined with AndScope/OrScope to build up the
+// overall crawl policy.
+type Scope interface {
+	Allow(u *url.URL, depth int) bool
+}
+
+// frontierItem is a single entry on the crawl frontier.
+type frontierItem struct {
+	url    *url.URL
+	depth  int
+	parent *url.URL
+	tag    LinkType
+}
+
+// Crawler drives a worker pool of Fetchers over a deduplicating frontier,
+// bounded by MaxDepth and filtered by Scope.
+//
+// The frontier is an unbounded, mutex-guarded queue rather than a
+// channel: workers both consume it and push newly-discovered URLs back
+// onto it, so a bounded channel would let every worker block trying to
+// push at once, with nothing left to drain it.
+type Crawler struct {
+	Fetcher  Fetcher
+	Scope    Scope
+	Workers  int
+	MaxDepth int
+	Results  chan *Result
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	queue   []frontierItem
+	pending int
+	seen    map[string]bool
+}
+
+// NewCrawler builds a Crawler with the given Fetcher, Scope, worker count
+// and maximum crawl depth.
+func NewCrawler(fetcher Fetcher, scope Scope, workers, maxDepth int) *Crawler {
+	if workers < 1 {
+		workers = 1
+	}
+
+	c := &Crawler{
+		Fetcher:  fetcher,
+		Scope:    scope,
+		Workers:  workers,
+		MaxDepth: maxDepth,
+		Results:  make(chan *Result, workers*4),
+		seen:     make(map[string]bool),
+	}
+	c.cond = sync.NewCond(&c.mu)
+
+	return c
+}
+
+// Run crawls the given seed URLs to completion, blocking until the
+// frontier is drained, then closes the Results channel. Consumers should
+// range over c.Results in a separate goroutine started before Run is
+// called.
+func (c *Crawler) Run(seeds []*url.URL) error {
+	for _, seed := range seeds {
+		c.enqueue(frontierItem{url: seed, depth: 0, tag: LinkTypePrimary})
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(c.Workers)
+
+	for i := 0; i < c.Workers; i++ {
+		go func() {
+			defer wg.Done()
+			c.worker()
+		}()
+	}
+
+	wg.Wait()
+	close(c.Results)
+
+	return nil
+}
+
+// enqueue adds item to the frontier if its URL hasn't been seen before,
+// waking any worker blocked waiting for work.
+func (c *Crawler) enqueue(item frontierItem) {
+	key := item.url.String()
+
+	c.mu.Lock()
+	if c.seen[key] {
+		c.mu.Unlock()
+		return
+	}
+	c.seen[key] = true
+	c.pending++
+	c.queue = append(c.queue, item)
+	c.mu.Unlock()
+
+	c.cond.Broadcast()
+}
+
+// dequeue blocks until an item is available, or returns ok=false once
+// the frontier is fully drained (nothing queued and nothing in flight).
+func (c *Crawler) dequeue() (item frontierItem, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for len(c.queue) == 0 {
+		if c.pending == 0 {
+			return frontierItem{}, false
+		}
+		c.cond.Wait()
+	}
+
+	item, c.queue = c.queue[0], c.queue[1:]
+
+	return item, true
+}
+
+// worker repeatedly dequeues and processes frontier items until the
+// frontier is drained.
+func (c *Crawler) worker() {
+	for {
+		item, ok := c.dequeue()
+		if !ok {
+			return
+		}
+
+		c.process(item)
+
+		c.mu.Lock()
+		c.pending--
+		c.mu.Unlock()
+		c.cond.Broadcast()
+	}
+}
+
+// process fetches a single frontier item, emits its Result, and enqueues
+// any primary links it discovered that are still in scope.
+func (c *Crawler) process(item frontierItem) {
+	if item.depth > c.MaxDepth {
+		return
+	}
+
+	if c.Scope != nil && !c.Scope.Allow(item.url, item.depth) {
+		return
+	}
+
+	urls, assets, err := c.Fetcher.Fetch(item.url)
+
+	c.Results <- &Result{
+		URL:    item.url,
+		Depth:  item.depth,
+		Parent: item.parent,
+		Tag:    item.tag,
+		Assets: assets,
+		Err:    err,
+	}
+
+	if err != nil {
+		log.Warnf("Fetch failed for %s: %v", item.url, err)
+		return
+	}
+
+	for _, u := range urls {
+		depth := item.depth + 1
+		if depth > c.MaxDepth || (c.Scope != nil && !c.Scope.Allow(u, depth)) {
+			continue
+		}
+		c.enqueue(frontierItem{url: u, depth: depth, parent: item.url, tag: LinkTypePrimary})
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// fanOutFetcher returns width child links for the seed URL and none for
+// any child, so a single fetch produces more frontier items than a
+// small worker pool's old bounded jobs-channel buffer could ever hold.
+type fanOutFetcher struct {
+	width int
+}
+
+func (f *fanOutFetcher) Fetch(target *url.URL) ([]*url.URL, []*url.URL, error) {
+	if target.Path != "/" {
+		return nil, nil, nil
+	}
+
+	urls := make([]*url.URL, 0, f.width)
+	for i := 0; i < f.width; i++ {
+		u, err := url.Parse(fmt.Sprintf("http://example.com/page%d", i))
+		if err != nil {
+			return nil, nil, err
+		}
+		urls = append(urls, u)
+	}
+
+	return urls, nil, nil
+}
+
+func TestCrawlerRunDoesNotDeadlockOnWideFanOut(t *testing.T) {
+	seed, err := url.Parse("http://example.com/")
+	if err != nil {
+		t.Fatalf("failed to parse seed URL: %v", err)
+	}
+
+	crawler := NewCrawler(&fanOutFetcher{width: 50}, &SameHostScope{Host: seed.Host}, 2, 1)
+
+	go func() {
+		for range crawler.Results {
+		}
+	}()
+
+	done := make(chan error, 1)
+	go func() { done <- crawler.Run([]*url.URL{seed}) }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run() returned error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() deadlocked: did not return within 5s")
+	}
+}
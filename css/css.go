@@ -0,0 +1,41 @@
+// Package css provides minimal tokenizing of CSS text to recover url(...)
+// references, for use by callers that need to discover assets (images,
+// fonts, imported stylesheets) referenced from style rules rather than
+// HTML markup.
+package css
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// urlPattern matches a url(...) token, with or without quotes, including
+// the url(...) that follows an @import. Go's regexp package (RE2) has no
+// backreferences, so the opening and closing quotes are matched
+// independently rather than required to agree.
+var urlPattern = regexp.MustCompile(`url\(\s*['"]?([^'")]*)['"]?\s*\)`)
+
+// ExtractURLs scans raw CSS text - a stylesheet body, an inline style
+// attribute, or the contents of a <style> tag - and returns every
+// url(...) reference it contains, resolved against base. data: URIs are
+// skipped.
+func ExtractURLs(base *url.URL, text string) []*url.URL {
+	var urls []*url.URL
+
+	for _, m := range urlPattern.FindAllStringSubmatch(text, -1) {
+		raw := strings.TrimSpace(m[1])
+		if raw == "" || strings.HasPrefix(raw, "data:") {
+			continue
+		}
+
+		ref, err := url.Parse(raw)
+		if err != nil {
+			continue
+		}
+
+		urls = append(urls, base.ResolveReference(ref))
+	}
+
+	return urls
+}
@@ -0,0 +1,71 @@
+package css
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestExtractURLs(t *testing.T) {
+	base, err := url.Parse("http://example.com/a/b/s.css")
+	if err != nil {
+		t.Fatalf("failed to parse base URL: %v", err)
+	}
+
+	cases := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "double quoted",
+			text: `background: url("img.png");`,
+			want: []string{"http://example.com/a/b/img.png"},
+		},
+		{
+			name: "single quoted",
+			text: `background: url('img.png');`,
+			want: []string{"http://example.com/a/b/img.png"},
+		},
+		{
+			name: "unquoted",
+			text: `background: url(img.png);`,
+			want: []string{"http://example.com/a/b/img.png"},
+		},
+		{
+			name: "import",
+			text: `@import url("other.css");`,
+			want: []string{"http://example.com/a/b/other.css"},
+		},
+		{
+			name: "data URI skipped",
+			text: `background: url(data:image/png;base64,AAAA);`,
+			want: nil,
+		},
+		{
+			name: "root-relative path",
+			text: `background: url("/assets/img.png");`,
+			want: []string{"http://example.com/assets/img.png"},
+		},
+		{
+			name: "multiple",
+			text: `a { background: url(one.png); } b { background: url("two.png"); }`,
+			want: []string{"http://example.com/a/b/one.png", "http://example.com/a/b/two.png"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := ExtractURLs(base, c.text)
+
+			if len(got) != len(c.want) {
+				t.Fatalf("ExtractURLs() = %v, want %v", got, c.want)
+			}
+
+			for i, u := range got {
+				if u.String() != c.want[i] {
+					t.Errorf("ExtractURLs()[%d] = %s, want %s", i, u.String(), c.want[i])
+				}
+			}
+		})
+	}
+}
@@ -1,19 +1,33 @@
 package main
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/http/httputil"
 	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	html "code.google.com/p/go.net/html"
-	atom "code.google.com/p/go.net/html/atom"
 	"github.com/PuerkitoBio/goquery"
 	log "github.com/cihub/seelog"
+	"github.com/sniperkit/kraken/css"
+	"github.com/sniperkit/kraken/sitespecific"
+	"github.com/sniperkit/kraken/warc"
 )
 
-var (
-	InvalidNode                 = errors.New("Node is not an anchor")
-	InvalidNodeAttributeMissing = errors.New("Node does not contain the specified attribute")
+var ErrDisallowedByRobots = errors.New("URL disallowed by robots.txt")
+
+const (
+	defaultUserAgent  = "krakenbot/1.0 (+https://github.com/sniperkit/kraken)"
+	defaultTimeout    = 30 * time.Second
+	defaultMaxRetries = 3
+	defaultRPS        = 1.0
 )
 
 type Fetcher interface {
@@ -22,159 +36,328 @@ type Fetcher interface {
 	Fetch(target *url.URL) (urls []*url.URL, assets []*url.URL, err error)
 }
 
-type HttpFetcher struct{}
+// HttpFetcher fetches pages over HTTP(S), extracting links and assets.
+// Construct one with NewHttpFetcher so the politeness defaults (retries,
+// per-host rate limiting, robots.txt) are wired up; the zero value works
+// too, but fetches with no rate limiting or robots.txt enforcement.
+type HttpFetcher struct {
+	// Warc, if set, receives a request/response record pair for every
+	// page fetched.
+	Warc *warc.Writer
+
+	// UserAgent is sent on every request and used to match robots.txt
+	// rules. Defaults to defaultUserAgent when built via NewHttpFetcher.
+	UserAgent string
+
+	// MaxRetries bounds the number of retry attempts on 5xx, 429, or
+	// network errors.
+	MaxRetries int
+
+	client     *http.Client
+	politeness *Politeness
+}
+
+// Option configures an HttpFetcher built with NewHttpFetcher.
+type Option func(*HttpFetcher)
+
+// WithUserAgent sets the User-Agent header sent on every request and
+// used when evaluating robots.txt.
+func WithUserAgent(ua string) Option {
+	return func(h *HttpFetcher) { h.UserAgent = ua }
+}
+
+// WithTimeout sets the per-request HTTP client timeout.
+func WithTimeout(d time.Duration) Option {
+	return func(h *HttpFetcher) { h.client.Timeout = d }
+}
+
+// WithMaxRetries bounds the number of retry attempts on 5xx, 429, or
+// network errors.
+func WithMaxRetries(n int) Option {
+	return func(h *HttpFetcher) { h.MaxRetries = n }
+}
 
-// Fetch retrieves the page at the specified URL and extracts URLs
+// WithRPS sets the maximum requests per second issued to any single host.
+func WithRPS(rps float64) Option {
+	return func(h *HttpFetcher) { h.politeness.RPS = rps }
+}
+
+// WithTransport overrides the HTTP client's RoundTripper, e.g. to inject
+// a fake transport in tests.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(h *HttpFetcher) { h.client.Transport = rt }
+}
+
+// WithWarc attaches a WARC writer that receives a request/response
+// record pair for every page fetched.
+func WithWarc(w *warc.Writer) Option {
+	return func(h *HttpFetcher) { h.Warc = w }
+}
+
+// NewHttpFetcher builds an HttpFetcher with sane polite-crawling
+// defaults, customisable via Option.
+func NewHttpFetcher(opts ...Option) *HttpFetcher {
+	h := &HttpFetcher{
+		UserAgent:  defaultUserAgent,
+		MaxRetries: defaultMaxRetries,
+		client:     &http.Client{Timeout: defaultTimeout},
+	}
+	h.politeness = newPoliteness(defaultRPS)
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// Fetch retrieves the page at the specified URL and extracts URLs. The
+// request is issued directly (rather than via goquery.NewDocument) so
+// the raw bytes are available to tee into Warc before the body is
+// parsed, and so retries, rate limiting and robots.txt can be applied.
 func (h *HttpFetcher) Fetch(target *url.URL) ([]*url.URL, []*url.URL, error) {
 
-	doc, err := goquery.NewDocument(target.String())
-	if err != nil {
-		return nil, nil, err
+	if h.politeness != nil && !h.politeness.Allowed(h.client, target, h.UserAgent) {
+		return nil, nil, ErrDisallowedByRobots
 	}
 
-	urls, err := h.extractLinks(doc)
+	reqDump, respDump, body, err := h.do(target)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	assets, err := h.extractAssets(doc)
+	if h.Warc != nil {
+		if err := h.Warc.WriteRequest(target.String(), reqDump); err != nil {
+			log.Warnf("Failed to write WARC request record for %s: %v", target, err)
+		}
+		if err := h.Warc.WriteResponse(target.String(), append(respDump, body...)); err != nil {
+			log.Warnf("Failed to write WARC response record for %s: %v", target, err)
+		}
+	}
+
+	node, err := html.Parse(bytes.NewReader(body))
 	if err != nil {
 		return nil, nil, err
 	}
 
+	doc := goquery.NewDocumentFromNode(node)
+	doc.Url = target
+
+	tableUrls, tableAssets := h.extractTable(doc)
+	assets := h.extractAssets(doc, tableAssets)
+
+	siteUrls, siteAssets, siteErrs := sitespecific.ExtractFor(target, doc, body)
+	for _, siteErr := range siteErrs {
+		log.Warnf("Site-specific extractor failed for %s: %v", target, siteErr)
+	}
+	urls := h.dedupeUrls(append(tableUrls, siteUrls...))
+	assets = h.dedupeUrls(append(assets, siteAssets...))
+
 	log.Debugf("URLs: %+v", urls)
 	log.Debugf("Assets: %+v", assets)
 
 	return urls, assets, nil
 }
 
-// extractLinks from a document
-func (h *HttpFetcher) extractLinks(doc *goquery.Document) ([]*url.URL, error) {
+// RegisterSiteExtractor registers a site-specific extractor that
+// HttpFetcher.Fetch consults, in registration order, in addition to the
+// generic tag-table scan.
+func RegisterSiteExtractor(e sitespecific.SiteExtractor) {
+	sitespecific.Register(e)
+}
 
-	// Blank slice to hold the links on this page
-	urls := make([]*url.URL, 0)
+// do issues the GET request for target, retrying on 5xx, 429, or network
+// errors with exponential backoff and jitter, honoring Retry-After when
+// present. It returns the dumped request and response headers plus the
+// response body, all raw bytes ready to tee into a WARC writer.
+func (h *HttpFetcher) do(target *url.URL) (reqDump, respDump, body []byte, err error) {
+
+	client := h.client
+	if client == nil {
+		client = http.DefaultClient
+	}
 
-	// Extract all 'a' elements from the document
-	sel := doc.Find("a")
-	if sel == nil {
-		// Assume zero links on failure
-		return nil, nil
+	if h.politeness != nil {
+		h.politeness.Wait(target.Host)
 	}
 
-	// Range over links, and add them to the list if valid
-	for _, n := range sel.Nodes {
+	var lastErr error
+	for attempt := 0; attempt <= h.MaxRetries; attempt++ {
+		if attempt > 0 && h.politeness != nil {
+			h.politeness.Wait(target.Host)
+		}
+
+		req, reqErr := http.NewRequest("GET", target.String(), nil)
+		if reqErr != nil {
+			return nil, nil, nil, reqErr
+		}
+		if h.UserAgent != "" {
+			req.Header.Set("User-Agent", h.UserAgent)
+		}
+
+		dump, dumpErr := httputil.DumpRequestOut(req, false)
+		if dumpErr != nil {
+			return nil, nil, nil, dumpErr
+		}
 
-		// Validate the node is a link, and extract the target URL
-		href, err := h.extractValidHref(n)
-		if err != nil || href == "" {
+		resp, doErr := client.Do(req)
+		if doErr != nil {
+			lastErr = doErr
+			log.Debugf("Fetch attempt %d for %s failed: %v", attempt, target, doErr)
+			backoff(attempt, 0)
 			continue
 		}
 
-		// Normalise the URL and add if valid
-		if uri := h.normaliseUrl(doc.Url, href); uri != nil {
-			urls = append(urls, uri)
+		if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+			retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"))
+			resp.Body.Close()
+			lastErr = fmt.Errorf("unexpected status %s for %s", resp.Status, target)
+			log.Debugf("Fetch attempt %d for %s returned %s, retrying", attempt, target, resp.Status)
+			backoff(attempt, retryAfter)
+			continue
+		}
+
+		respD, respErr := httputil.DumpResponse(resp, false)
+		if respErr != nil {
+			resp.Body.Close()
+			return nil, nil, nil, respErr
+		}
+
+		b, readErr := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if readErr != nil {
+			return nil, nil, nil, readErr
 		}
+
+		return dump, respD, b, nil
 	}
 
-	return h.dedupeUrls(urls), nil
+	return nil, nil, nil, lastErr
 }
 
-// extractAssets from a document
-// @todo break this up and add tests
-func (h *HttpFetcher) extractAssets(doc *goquery.Document) ([]*url.URL, error) {
+// backoff sleeps for retryAfter if set, otherwise for an exponentially
+// increasing, jittered delay based on attempt.
+func backoff(attempt int, retryAfter time.Duration) {
+	if retryAfter > 0 {
+		time.Sleep(retryAfter)
+		return
+	}
 
-	var sel *goquery.Selection
-	assets := make([]*url.URL, 0)
+	base := time.Duration(1<<uint(attempt)) * 100 * time.Millisecond
+	time.Sleep(base + time.Duration(rand.Int63n(int64(base)+1)))
+}
 
-	// First grab all the images
-	sel = doc.Find("img")
-	for _, n := range sel.Nodes {
-		if n == nil {
-			continue
-		}
-		for _, a := range n.Attr {
-			if a.Key == "src" && a.Val != "" {
-				if uri := h.normaliseUrl(doc.Url, a.Val); uri != nil {
-					assets = append(assets, uri)
-					break
-				}
+// parseRetryAfter parses a Retry-After header value, which may be given
+// as either a number of seconds or an HTTP date.
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
 
-			}
-		}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second
 	}
 
-	// Next scripts
-	sel = doc.Find("script")
-	for _, n := range sel.Nodes {
-		if n == nil {
-			continue
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t)
+	}
+
+	return 0
+}
+
+// extractAssets scans doc for the CSS-specific passes beyond the
+// KindAsset rows extractTable already found: stylesheet bodies
+// (recursed into for their own url(...) references) and
+// inline/embedded CSS. table is the KindAsset half of extractTable's
+// result, merged in here rather than walked again.
+func (h *HttpFetcher) extractAssets(doc *goquery.Document, table []*url.URL) []*url.URL {
+	assets := table
+
+	doc.Find(`link[rel="stylesheet"][href]`).Each(func(i int, sel *goquery.Selection) {
+		href, _ := sel.Attr("href")
+		if uri := h.normaliseUrl(doc.Url, href); uri != nil {
+			assets = append(assets, h.extractStylesheetAssets(uri)...)
 		}
-		for _, a := range n.Attr {
-			if a.Key == "src" && a.Val != "" {
-				if uri := h.normaliseUrl(doc.Url, a.Val); uri != nil {
-					assets = append(assets, uri)
-					break
-				}
+	})
+
+	assets = append(assets, h.extractCSSAssets(doc)...)
+
+	return h.dedupeUrls(assets)
+}
 
+// extractTable walks LinkMatches once, partitioning every matching
+// attribute value into links and assets by its row's Kind.
+func (h *HttpFetcher) extractTable(doc *goquery.Document) (urls, assets []*url.URL) {
+	for _, m := range LinkMatches {
+		doc.Find(m.Selector).Each(func(i int, sel *goquery.Selection) {
+			if m.Filter != nil && !m.Filter(sel) {
+				return
 			}
-		}
-	}
 
-	// Links, eg styles, shortcut icons etc
-	sel = doc.Find("link")
-	for _, n := range sel.Nodes {
-		if n == nil {
-			continue
-		}
+			value, ok := sel.Attr(m.Attr)
+			if !ok || value == "" {
+				return
+			}
 
-		// Pull out various fields
-		var rel, linktype string
-		var uri *url.URL
-		for _, a := range n.Attr {
-			switch a.Key {
-			case "rel":
-				rel = a.Val
-			case "type":
-				linktype = a.Val
-			case "href":
-				uri = h.normaliseUrl(doc.Url, a.Val)
+			raws := []string{value}
+			if m.Parse != nil {
+				raws = m.Parse(value)
 			}
-		}
 
-		// Continue if there is no link target
-		if uri == nil {
-			continue
-		}
+			for _, raw := range raws {
+				uri := h.normaliseUrl(doc.Url, raw)
+				if uri == nil {
+					continue
+				}
 
-		// Otherwise select specific combinations
-		switch {
-		case rel == "stylesheet" && linktype == "text/css":
-			assets = append(assets, uri)
-		case rel == "shortcut icon":
-			assets = append(assets, uri)
-		}
+				if m.Kind == KindLink {
+					urls = append(urls, uri)
+				} else {
+					assets = append(assets, uri)
+				}
+			}
+		})
 	}
 
-	return h.dedupeUrls(assets), nil
+	return h.dedupeUrls(urls), h.dedupeUrls(assets)
 }
 
-// validateLink is an anchor with a href, and extract normalised url
-func (h *HttpFetcher) extractValidHref(n *html.Node) (string, error) {
-	var href string
+// extractCSSAssets scans inline style="..." attributes and the bodies of
+// <style> tags for url(...) references.
+func (h *HttpFetcher) extractCSSAssets(doc *goquery.Document) []*url.URL {
+	var assets []*url.URL
+
+	doc.Find("[style]").Each(func(i int, sel *goquery.Selection) {
+		if style, ok := sel.Attr("style"); ok {
+			assets = append(assets, css.ExtractURLs(doc.Url, style)...)
+		}
+	})
+
+	doc.Find("style").Each(func(i int, sel *goquery.Selection) {
+		assets = append(assets, css.ExtractURLs(doc.Url, sel.Text())...)
+	})
 
-	// Confirm this node is an anchor element
-	if n == nil || n.Type != html.ElementNode || n.DataAtom != atom.A {
-		return href, InvalidNode
+	return assets
+}
+
+// extractStylesheetAssets fetches a linked stylesheet and scans its body
+// for url(...) references, resolving them against the stylesheet's own
+// URL rather than the document's. The fetch goes through the same
+// politeness and retry path as the page fetch itself, so stylesheet
+// hosts get the same rate limiting, retries and robots.txt enforcement.
+func (h *HttpFetcher) extractStylesheetAssets(sheet *url.URL) []*url.URL {
+	if h.politeness != nil && !h.politeness.Allowed(h.client, sheet, h.UserAgent) {
+		log.Debugf("Stylesheet %s disallowed by robots.txt", sheet)
+		return nil
 	}
 
-	// Return the value of the href attr if it exists
-	for _, a := range n.Attr {
-		if a.Key == "href" && a.Val != "" {
-			return a.Val, nil
-		}
+	_, _, body, err := h.do(sheet)
+	if err != nil {
+		log.Debugf("Failed to fetch stylesheet %s: %v", sheet, err)
+		return nil
 	}
 
-	return "", InvalidNodeAttributeMissing
+	return css.ExtractURLs(sheet, string(body))
 }
 
 // normaliseUrl converts relative URLs to absolute URLs
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+// fakeTransport serves canned responses by path, recording how many
+// times each path was requested.
+type fakeTransport struct {
+	responses map[string][]*http.Response
+
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (f *fakeTransport) count(path string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[path]
+}
+
+func (f *fakeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	path := req.URL.Path
+
+	f.mu.Lock()
+	if f.calls == nil {
+		f.calls = map[string]int{}
+	}
+	f.calls[path]++
+	n := f.calls[path]
+	f.mu.Unlock()
+
+	resps := f.responses[path]
+	if len(resps) == 0 {
+		return &http.Response{StatusCode: http.StatusNotFound, Body: ioutil.NopCloser(bytes.NewReader(nil)), Header: http.Header{}}, nil
+	}
+
+	idx := n - 1
+	if idx >= len(resps) {
+		idx = len(resps) - 1
+	}
+
+	return resps[idx], nil
+}
+
+func newResponse(status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(body)),
+		Header:     http.Header{},
+	}
+}
+
+func TestHttpFetcherDoRetriesOn5xxThenSucceeds(t *testing.T) {
+	ft := &fakeTransport{
+		responses: map[string][]*http.Response{
+			"/page":       {newResponse(http.StatusServiceUnavailable, ""), newResponse(http.StatusOK, "<html></html>")},
+			"/robots.txt": {newResponse(http.StatusNotFound, "")},
+		},
+	}
+
+	h := NewHttpFetcher(WithTransport(ft), WithMaxRetries(2))
+
+	_, _, err := h.Fetch(mustParseURL(t, "http://example.com/page"))
+	if err != nil {
+		t.Fatalf("Fetch() error: %v", err)
+	}
+
+	if got := ft.count("/page"); got != 2 {
+		t.Errorf("got %d requests for /page, want 2 (one retry)", got)
+	}
+}
+
+func TestHttpFetcherDoGivesUpAfterMaxRetries(t *testing.T) {
+	ft := &fakeTransport{
+		responses: map[string][]*http.Response{
+			"/page":       {newResponse(http.StatusServiceUnavailable, "")},
+			"/robots.txt": {newResponse(http.StatusNotFound, "")},
+		},
+	}
+
+	h := NewHttpFetcher(WithTransport(ft), WithMaxRetries(2))
+
+	_, _, err := h.Fetch(mustParseURL(t, "http://example.com/page"))
+	if err == nil {
+		t.Fatal("expected an error after exhausting retries")
+	}
+
+	if got := ft.count("/page"); got != 3 {
+		t.Errorf("got %d requests for /page, want 3 (1 initial + 2 retries)", got)
+	}
+}
+
+func TestHttpFetcherFetchHonorsRobots(t *testing.T) {
+	ft := &fakeTransport{
+		responses: map[string][]*http.Response{
+			"/robots.txt": {newResponse(http.StatusOK, "User-agent: *\nDisallow: /private\n")},
+		},
+	}
+
+	h := NewHttpFetcher(WithTransport(ft))
+
+	_, _, err := h.Fetch(mustParseURL(t, "http://example.com/private/page"))
+	if err != ErrDisallowedByRobots {
+		t.Errorf("got error %v, want ErrDisallowedByRobots", err)
+	}
+
+	if got := ft.count("/private/page"); got != 0 {
+		t.Errorf("got %d requests for a robots-disallowed page, want 0", got)
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// LinkKind classifies what a LinkMatch row extracts: a link the crawler
+// should follow, or an asset it should only archive.
+type LinkKind int
+
+const (
+	// KindLink marks a reference the crawler may recurse into.
+	KindLink LinkKind = iota
+	// KindAsset marks a reference that is archived but never crawled.
+	KindAsset
+)
+
+// LinkMatch is one row of the declarative extraction table: a selector
+// and attribute to read, what kind of reference it yields, and an
+// optional Parse to split an attribute value into one or more raw URLs
+// (srcset candidates, the "URL=" token of a meta refresh).
+type LinkMatch struct {
+	Selector string
+	Attr     string
+	Kind     LinkKind
+	Parse    func(value string) []string
+	// Filter, if set, is consulted for each element the selector
+	// matches and must return true for the element to be processed.
+	// Used when a condition can't be expressed in the CSS selector
+	// itself, e.g. a case-insensitive attribute value.
+	Filter func(sel *goquery.Selection) bool
+}
+
+// LinkMatches is the table of tag/attribute combinations HttpFetcher
+// scans for links and assets. Callers may append custom rows, e.g. to
+// pick up a bespoke data-* attribute.
+var LinkMatches = []LinkMatch{
+	{Selector: "a[href]", Attr: "href", Kind: KindLink},
+	{Selector: "area[href]", Attr: "href", Kind: KindLink},
+	{Selector: "link[href]", Attr: "href", Kind: KindAsset},
+	{Selector: "img[src]", Attr: "src", Kind: KindAsset},
+	{Selector: "img[srcset]", Attr: "srcset", Kind: KindAsset, Parse: parseSrcset},
+	// source and img cover <picture> children; goquery's Find matches
+	// them regardless of the parent picture wrapper.
+	{Selector: "source[src]", Attr: "src", Kind: KindAsset},
+	{Selector: "source[srcset]", Attr: "srcset", Kind: KindAsset, Parse: parseSrcset},
+	{Selector: "video[src]", Attr: "src", Kind: KindAsset},
+	{Selector: "video[poster]", Attr: "poster", Kind: KindAsset},
+	{Selector: "audio[src]", Attr: "src", Kind: KindAsset},
+	{Selector: "iframe[src]", Attr: "src", Kind: KindAsset},
+	{Selector: "embed[src]", Attr: "src", Kind: KindAsset},
+	{Selector: "object[data]", Attr: "data", Kind: KindAsset},
+	{Selector: "script[src]", Attr: "src", Kind: KindAsset},
+	{Selector: "form[action]", Attr: "action", Kind: KindLink},
+	{Selector: "meta[content]", Attr: "content", Kind: KindLink, Parse: parseMetaRefresh, Filter: isMetaRefresh},
+}
+
+// isMetaRefresh reports whether sel is a <meta> tag whose http-equiv
+// attribute is "refresh", matched case-insensitively: the HTML spec
+// treats http-equiv values as ASCII case-insensitive, and real pages
+// write "Refresh" and "REFRESH" as often as the lower-case form.
+func isMetaRefresh(sel *goquery.Selection) bool {
+	v, ok := sel.Attr("http-equiv")
+	return ok && strings.EqualFold(v, "refresh")
+}
+
+// parseSrcset splits a srcset attribute into its candidate URLs,
+// dropping the trailing width/density descriptor from each.
+func parseSrcset(value string) []string {
+	var urls []string
+
+	for _, candidate := range strings.Split(value, ",") {
+		fields := strings.Fields(strings.TrimSpace(candidate))
+		if len(fields) > 0 {
+			urls = append(urls, fields[0])
+		}
+	}
+
+	return urls
+}
+
+// parseMetaRefresh pulls the redirect target out of a meta refresh
+// content attribute, e.g. "5;URL=/next-page" or "0; url='/next-page'".
+func parseMetaRefresh(value string) []string {
+	parts := strings.SplitN(value, ";", 2)
+	if len(parts) != 2 {
+		return nil
+	}
+
+	field := strings.TrimSpace(parts[1])
+
+	i := strings.IndexByte(field, '=')
+	if i < 0 || !strings.EqualFold(strings.TrimSpace(field[:i]), "url") {
+		return nil
+	}
+
+	target := strings.Trim(strings.TrimSpace(field[i+1:]), `"'`)
+	if target == "" {
+		return nil
+	}
+
+	return []string{target}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestParseSrcset(t *testing.T) {
+	cases := []struct {
+		name  string
+		value string
+		want  []string
+	}{
+		{"single", "a.jpg", []string{"a.jpg"}},
+		{"density descriptor", "a.jpg 1x, b.jpg 2x", []string{"a.jpg", "b.jpg"}},
+		{"width descriptor", "small.jpg 480w, big.jpg 800w", []string{"small.jpg", "big.jpg"}},
+		{"extra whitespace", "  a.jpg 1x ,  b.jpg 2x  ", []string{"a.jpg", "b.jpg"}},
+		{"empty", "", nil},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := parseSrcset(c.value)
+			if !stringSlicesEqual(got, c.want) {
+				t.Errorf("parseSrcset(%q) = %v, want %v", c.value, got, c.want)
+			}
+		})
+	}
+}
+
+func TestParseMetaRefresh(t *testing.T) {
+	cases := []struct {
+		name  string
+		value string
+		want  []string
+	}{
+		{"unquoted", "5;URL=/next-page", []string{"/next-page"}},
+		{"quoted", "0; url='/next-page'", []string{"/next-page"}},
+		{"double quoted", `0; url="/next-page"`, []string{"/next-page"}},
+		{"no target", "5", nil},
+		{"missing url field", "5; foo=bar", nil},
+		{"empty target", "5;URL=", nil},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := parseMetaRefresh(c.value)
+			if !stringSlicesEqual(got, c.want) {
+				t.Errorf("parseMetaRefresh(%q) = %v, want %v", c.value, got, c.want)
+			}
+		})
+	}
+}
+
+func TestIsMetaRefreshCaseInsensitive(t *testing.T) {
+	cases := []struct {
+		name string
+		html string
+		want bool
+	}{
+		{"lowercase", `<meta http-equiv="refresh" content="5;URL=/x">`, true},
+		{"titlecase", `<meta http-equiv="Refresh" content="5;URL=/x">`, true},
+		{"uppercase", `<meta http-equiv="REFRESH" content="5;URL=/x">`, true},
+		{"unrelated meta", `<meta name="description" content="a page">`, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(c.html))
+			if err != nil {
+				t.Fatalf("failed to parse fragment: %v", err)
+			}
+
+			got := isMetaRefresh(doc.Find("meta").First())
+			if got != c.want {
+				t.Errorf("isMetaRefresh(%q) = %v, want %v", c.html, got, c.want)
+			}
+		})
+	}
+}
+
+func stringSlicesEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -1,13 +1,20 @@
 package main
 
 import (
-	html "code.google.com/p/go.net/html"
-	atom "code.google.com/p/go.net/html/atom"
-	"github.com/PuerkitoBio/goquery"
+	"flag"
+	"net/url"
+
 	log "github.com/cihub/seelog"
+	"github.com/sniperkit/kraken/warc"
+)
+
+var (
+	output      = flag.String("output", "", "Write a WARC/1.0 archive of crawled requests and responses to this file (gzipped)")
+	warcMaxSize = flag.Int64("warc-max-size", 1<<30, "Rotate the WARC output file once it reaches this many bytes")
 )
 
 func main() {
+	flag.Parse()
 
 	// Flush logs before exit
 	defer log.Flush()
@@ -16,72 +23,45 @@ func main() {
 	Crawl("http://golang.org/")
 }
 
-// Crawl takes a URL and recursively crawls pages
-func Crawl(url string) {
+// Crawl takes a seed URL and recursively crawls same-host pages, logging
+// each result as it arrives.
+func Crawl(seed string) {
 
-	_, urls, err := fetch(url)
+	uri, err := url.Parse(seed)
 	if err != nil {
-		log.Errorf("Error:", err)
+		log.Errorf("Invalid seed URL: %v", err)
 		return
 	}
 
-	log.Infof("URLs found: %+v", urls)
-}
-
-// fetch retrieves the page at the specified URL and extracts URLs
-func fetch(url string) (string, []string, error) {
+	var opts []Option
 
-	doc, err := goquery.NewDocument(url)
-	if err != nil {
-		return "", nil, err
-	}
-
-	urls, err := extractLinks(doc)
-	if err != nil {
-		return "", urls, err
-	}
-
-	log.Debugf("URLs: %+v", urls)
-
-	return "", urls, nil
-}
-
-// extractLinks from a document
-func extractLinks(doc *goquery.Document) ([]string, error) {
-
-	// Blank slice to hold our links on this page
-	urls := make([]string, 0)
+	if *output != "" {
+		w, err := warc.NewWriter(*output, *warcMaxSize)
+		if err != nil {
+			log.Errorf("Failed to open WARC output %s: %v", *output, err)
+			return
+		}
+		defer w.Close()
 
-	// Extract all 'a' elements from the document
-	sel := doc.Find("a")
-	if sel == nil {
-		// Assume zero links on failure
-		return nil, nil
+		opts = append(opts, WithWarc(w))
 	}
 
-	// Range over links, and add them to our list if valid
-	for i, n := range sel.Nodes {
-		if n.Type != html.ElementNode || n.DataAtom != atom.A {
-			log.Debugf("Node is not an anchor: %v", n.Type)
-			continue
-		}
+	fetcher := NewHttpFetcher(opts...)
 
-		var href string
+	crawler := NewCrawler(fetcher, &SameHostScope{Host: uri.Host}, 4, 2)
 
-		for _, a := range n.Attr {
-			if a.Key != "href" {
+	go func() {
+		for res := range crawler.Results {
+			if res.Err != nil {
+				log.Warnf("%s: %v", res.URL, res.Err)
 				continue
 			}
-			href = a.Val
-		}
 
-		if href == "" {
-			continue
+			log.Infof("[depth %d] %s (%s, %d assets)", res.Depth, res.URL, res.Tag, len(res.Assets))
 		}
+	}()
 
-		log.Debugf("Node %v: %s", i, href)
-		urls = append(urls, href)
+	if err := crawler.Run([]*url.URL{uri}); err != nil {
+		log.Errorf("Crawl failed: %v", err)
 	}
-
-	return urls, nil
 }
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Politeness coordinates per-host request pacing and robots.txt
+// compliance shared by every request an HttpFetcher makes.
+type Politeness struct {
+	// RPS is the maximum number of requests per second issued to any
+	// single host.
+	RPS float64
+
+	mu       sync.Mutex
+	limiters map[string]*hostLimiter
+	robots   map[robotsCacheKey]*robotsRules
+}
+
+// robotsCacheKey scopes a cached robots.txt to the host it was fetched
+// from and the user agent its rules were matched against, since
+// different user agents can see different groups in the same file.
+type robotsCacheKey struct {
+	host      string
+	userAgent string
+}
+
+// newPoliteness builds a Politeness with the given per-host rate limit.
+func newPoliteness(rps float64) *Politeness {
+	return &Politeness{
+		RPS:      rps,
+		limiters: make(map[string]*hostLimiter),
+		robots:   make(map[robotsCacheKey]*robotsRules),
+	}
+}
+
+// Wait blocks until a request to host is permitted by its per-host rate
+// limit.
+func (p *Politeness) Wait(host string) {
+	p.mu.Lock()
+	l, ok := p.limiters[host]
+	if !ok {
+		l = newHostLimiter(p.RPS)
+		p.limiters[host] = l
+	}
+	p.mu.Unlock()
+
+	l.Wait()
+}
+
+// Allowed reports whether target is permitted by its host's robots.txt
+// for userAgent, fetching and caching the robots.txt once per
+// host/user-agent pair.
+func (p *Politeness) Allowed(client *http.Client, target *url.URL, userAgent string) bool {
+	rules := p.robotsFor(client, target, userAgent)
+	return rules.Allowed(target.Path)
+}
+
+func (p *Politeness) robotsFor(client *http.Client, target *url.URL, userAgent string) *robotsRules {
+	key := robotsCacheKey{host: target.Host, userAgent: userAgent}
+
+	p.mu.Lock()
+	rules, ok := p.robots[key]
+	p.mu.Unlock()
+	if ok {
+		return rules
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	rules = fetchRobots(client, target, userAgent)
+
+	p.mu.Lock()
+	p.robots[key] = rules
+	p.mu.Unlock()
+
+	return rules
+}
+
+// hostLimiter paces requests to a single host to at most RPS per second.
+type hostLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	last     time.Time
+}
+
+func newHostLimiter(rps float64) *hostLimiter {
+	l := &hostLimiter{}
+	if rps > 0 {
+		l.interval = time.Duration(float64(time.Second) / rps)
+	}
+	return l
+}
+
+// Wait blocks, if necessary, until at least one interval has elapsed
+// since the last request to this host.
+func (l *hostLimiter) Wait() {
+	if l.interval <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if !l.last.IsZero() {
+		if elapsed := now.Sub(l.last); elapsed < l.interval {
+			time.Sleep(l.interval - elapsed)
+			now = time.Now()
+		}
+	}
+	l.last = now
+}
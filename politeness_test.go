@@ -0,0 +1,87 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPolitenessAllowedHonorsRobots(t *testing.T) {
+	var hits int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Write([]byte("User-agent: *\nDisallow: /private\n"))
+	}))
+	defer srv.Close()
+
+	p := newPoliteness(0)
+	target := mustParseURL(t, srv.URL+"/private/page")
+
+	if p.Allowed(srv.Client(), target, "krakenbot") {
+		t.Error("expected /private/page to be disallowed")
+	}
+	if !p.Allowed(srv.Client(), mustParseURL(t, srv.URL+"/public"), "krakenbot") {
+		t.Error("expected /public to be allowed")
+	}
+}
+
+func TestPolitenessCachesRobotsPerHostAndUserAgent(t *testing.T) {
+	var hits int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Write([]byte("User-agent: *\nDisallow:\n"))
+	}))
+	defer srv.Close()
+
+	p := newPoliteness(0)
+	target := mustParseURL(t, srv.URL+"/")
+
+	p.Allowed(srv.Client(), target, "krakenbot")
+	p.Allowed(srv.Client(), target, "krakenbot")
+	if got := atomic.LoadInt32(&hits); got != 1 {
+		t.Errorf("got %d robots.txt fetches for repeated host+UA, want 1", got)
+	}
+
+	p.Allowed(srv.Client(), target, "othercrawler")
+	if got := atomic.LoadInt32(&hits); got != 2 {
+		t.Errorf("got %d robots.txt fetches after a new user agent, want 2", got)
+	}
+}
+
+func TestPolitenessAllowedDefaultsToAllowOnFetchError(t *testing.T) {
+	p := newPoliteness(0)
+	target := &url.URL{Scheme: "http", Host: "127.0.0.1:0", Path: "/"}
+
+	if !p.Allowed(nil, target, "krakenbot") {
+		t.Error("expected an unreachable robots.txt to be treated as allow-all")
+	}
+}
+
+func TestHostLimiterWaitIsNonBlockingWithoutRPS(t *testing.T) {
+	l := newHostLimiter(0)
+
+	start := time.Now()
+	l.Wait()
+	l.Wait()
+
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("Wait took %v with no rate limit configured, want near-instant", elapsed)
+	}
+}
+
+func TestHostLimiterWaitPacesRequests(t *testing.T) {
+	l := newHostLimiter(20) // 50ms between requests
+
+	start := time.Now()
+	l.Wait()
+	l.Wait()
+
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("Wait took %v for a second request, want at least ~50ms of pacing", elapsed)
+	}
+}
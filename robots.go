@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	log "github.com/cihub/seelog"
+)
+
+// robotsRules holds the Disallow/Allow paths from a robots.txt that
+// apply to the user-agent group we matched.
+type robotsRules struct {
+	disallow []string
+	allow    []string
+}
+
+// fetchRobots retrieves and parses the robots.txt for target's host,
+// keeping the directives that apply to userAgent. A missing or
+// unreadable robots.txt is treated as allow-all.
+func fetchRobots(client *http.Client, target *url.URL, userAgent string) *robotsRules {
+	robotsURL := &url.URL{Scheme: target.Scheme, Host: target.Host, Path: "/robots.txt"}
+
+	resp, err := client.Get(robotsURL.String())
+	if err != nil {
+		log.Debugf("Failed to fetch robots.txt for %s: %v", target.Host, err)
+		return &robotsRules{}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &robotsRules{}
+	}
+
+	return parseRobots(resp.Body, userAgent)
+}
+
+// parseRobots reads a robots.txt body and returns the directives from
+// the group that applies to userAgent, per the standard group-selection
+// rule: the group whose name is the longest match against userAgent's
+// product token, falling back to the wildcard "User-agent: *" group if
+// no named group matches.
+func parseRobots(r io.Reader, userAgent string) *robotsRules {
+	groups := map[string]*robotsRules{}
+	var current []string
+	sawDirective := false
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		field := strings.ToLower(strings.TrimSpace(parts[0]))
+		value := strings.TrimSpace(parts[1])
+
+		switch field {
+		case "user-agent":
+			// Consecutive User-agent lines belong to the same group; a
+			// User-agent line following a directive starts a new one.
+			if sawDirective {
+				current = nil
+				sawDirective = false
+			}
+			name := strings.ToLower(value)
+			current = append(current, name)
+			if _, ok := groups[name]; !ok {
+				groups[name] = &robotsRules{}
+			}
+		case "disallow":
+			sawDirective = true
+			if value != "" {
+				for _, name := range current {
+					groups[name].disallow = append(groups[name].disallow, value)
+				}
+			}
+		case "allow":
+			sawDirective = true
+			if value != "" {
+				for _, name := range current {
+					groups[name].allow = append(groups[name].allow, value)
+				}
+			}
+		}
+	}
+
+	if rules := matchGroup(groups, userAgent); rules != nil {
+		return rules
+	}
+
+	return &robotsRules{}
+}
+
+// matchGroup picks the group whose name is the longest prefix of the
+// product token of userAgent (the part before "/" or whitespace), per
+// the REP convention that a group name matches when it is a prefix of
+// (or equal to) the crawler's product token. Falls back to the wildcard
+// group. It returns nil if neither exists.
+func matchGroup(groups map[string]*robotsRules, userAgent string) *robotsRules {
+	token := strings.ToLower(userAgent)
+	if i := strings.IndexAny(token, "/ \t"); i >= 0 {
+		token = token[:i]
+	}
+
+	var best string
+	var match *robotsRules
+	for name, rules := range groups {
+		if name == "*" || token == "" || !strings.HasPrefix(token, name) {
+			continue
+		}
+		if len(name) > len(best) {
+			best = name
+			match = rules
+		}
+	}
+
+	if match != nil {
+		return match
+	}
+
+	return groups["*"]
+}
+
+// Allowed reports whether path is permitted, using the longest-match-wins
+// convention: the most specific Allow/Disallow rule applies.
+func (r *robotsRules) Allowed(path string) bool {
+	best := ""
+	allowed := true
+
+	for _, p := range r.disallow {
+		if strings.HasPrefix(path, p) && len(p) > len(best) {
+			best = p
+			allowed = false
+		}
+	}
+
+	for _, p := range r.allow {
+		if strings.HasPrefix(path, p) && len(p) > len(best) {
+			best = p
+			allowed = true
+		}
+	}
+
+	return allowed
+}
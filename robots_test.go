@@ -0,0 +1,106 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseRobotsWildcardGroup(t *testing.T) {
+	body := `
+User-agent: *
+Disallow: /private
+Allow: /private/public
+`
+	rules := parseRobots(strings.NewReader(body), "krakenbot/1.0")
+
+	if rules.Allowed("/private/secret") {
+		t.Error("expected /private/secret to be disallowed")
+	}
+	if !rules.Allowed("/private/public") {
+		t.Error("expected the more specific Allow to win")
+	}
+	if !rules.Allowed("/about") {
+		t.Error("expected an unlisted path to be allowed")
+	}
+}
+
+func TestParseRobotsMatchesNamedGroupOverWildcard(t *testing.T) {
+	body := `
+User-agent: *
+Disallow: /
+
+User-agent: krakenbot
+Disallow: /admin
+Allow: /
+`
+	rules := parseRobots(strings.NewReader(body), "krakenbot/1.0 (+https://example.com)")
+
+	if !rules.Allowed("/") {
+		t.Error("expected the krakenbot group to apply instead of the blanket wildcard Disallow")
+	}
+	if rules.Allowed("/admin") {
+		t.Error("expected /admin to be disallowed under the krakenbot group")
+	}
+}
+
+func TestParseRobotsFallsBackToWildcard(t *testing.T) {
+	body := `
+User-agent: othercrawler
+Disallow: /
+
+User-agent: *
+Disallow: /secret
+`
+	rules := parseRobots(strings.NewReader(body), "krakenbot/1.0")
+
+	if !rules.Allowed("/") {
+		t.Error("expected the wildcard group to apply since no named group matches")
+	}
+	if rules.Allowed("/secret") {
+		t.Error("expected /secret to be disallowed by the wildcard group")
+	}
+}
+
+func TestParseRobotsDoesNotMatchUnrelatedSubstringGroup(t *testing.T) {
+	body := `
+User-agent: bot
+Disallow: /
+
+User-agent: *
+Disallow: /private
+`
+	rules := parseRobots(strings.NewReader(body), "krakenbot/1.0 (+https://example.com)")
+
+	if !rules.Allowed("/") {
+		t.Error("expected the wildcard group to apply: \"bot\" is not a prefix of \"krakenbot\", just a substring")
+	}
+	if rules.Allowed("/private") {
+		t.Error("expected /private to be disallowed under the wildcard group")
+	}
+}
+
+func TestParseRobotsGroupNameMatchIsCaseInsensitive(t *testing.T) {
+	body := `
+User-agent: KrakenBot
+Disallow: /admin
+`
+	rules := parseRobots(strings.NewReader(body), "krakenbot/1.0")
+
+	if rules.Allowed("/admin") {
+		t.Error("expected group name matching to be case-insensitive")
+	}
+}
+
+func TestRobotsRulesAllowedLongestMatchWins(t *testing.T) {
+	rules := &robotsRules{
+		disallow: []string{"/a"},
+		allow:    []string{"/a/b"},
+	}
+
+	if !rules.Allowed("/a/b/c") {
+		t.Error("expected the longer, more specific Allow rule to win")
+	}
+	if rules.Allowed("/a/x") {
+		t.Error("expected the shorter Disallow rule to apply outside the Allow prefix")
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"net/url"
+	"regexp"
+)
+
+// SameHostScope allows only URLs whose host matches Host exactly.
+type SameHostScope struct {
+	Host string
+}
+
+func (s *SameHostScope) Allow(u *url.URL, depth int) bool {
+	return u.Host == s.Host
+}
+
+// DepthScope allows URLs up to and including MaxDepth.
+type DepthScope struct {
+	MaxDepth int
+}
+
+func (s *DepthScope) Allow(u *url.URL, depth int) bool {
+	return depth <= s.MaxDepth
+}
+
+// RegexpScope allows URLs whose string form matches Pattern.
+type RegexpScope struct {
+	Pattern *regexp.Regexp
+}
+
+func (s *RegexpScope) Allow(u *url.URL, depth int) bool {
+	return s.Pattern.MatchString(u.String())
+}
+
+// SeedListScope allows only hosts present in the original seed list.
+type SeedListScope struct {
+	Hosts map[string]bool
+}
+
+// NewSeedListScope builds a SeedListScope from the hosts of the given
+// seed URLs.
+func NewSeedListScope(seeds []*url.URL) *SeedListScope {
+	hosts := make(map[string]bool, len(seeds))
+	for _, s := range seeds {
+		hosts[s.Host] = true
+	}
+
+	return &SeedListScope{Hosts: hosts}
+}
+
+func (s *SeedListScope) Allow(u *url.URL, depth int) bool {
+	return s.Hosts[u.Host]
+}
+
+// AndScope allows a URL only if every child Scope allows it.
+type AndScope struct {
+	Scopes []Scope
+}
+
+func (s *AndScope) Allow(u *url.URL, depth int) bool {
+	for _, sc := range s.Scopes {
+		if !sc.Allow(u, depth) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// OrScope allows a URL if any child Scope allows it.
+type OrScope struct {
+	Scopes []Scope
+}
+
+func (s *OrScope) Allow(u *url.URL, depth int) bool {
+	for _, sc := range s.Scopes {
+		if sc.Allow(u, depth) {
+			return true
+		}
+	}
+
+	return false
+}
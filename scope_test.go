@@ -0,0 +1,94 @@
+package main
+
+import (
+	"net/url"
+	"regexp"
+	"testing"
+)
+
+func mustParseURL(t *testing.T, raw string) *url.URL {
+	t.Helper()
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("failed to parse %q: %v", raw, err)
+	}
+
+	return u
+}
+
+func TestSameHostScope(t *testing.T) {
+	scope := &SameHostScope{Host: "example.com"}
+
+	if !scope.Allow(mustParseURL(t, "http://example.com/a"), 0) {
+		t.Error("expected same host to be allowed")
+	}
+	if scope.Allow(mustParseURL(t, "http://other.com/a"), 0) {
+		t.Error("expected different host to be disallowed")
+	}
+}
+
+func TestDepthScope(t *testing.T) {
+	scope := &DepthScope{MaxDepth: 2}
+	u := mustParseURL(t, "http://example.com/")
+
+	if !scope.Allow(u, 2) {
+		t.Error("expected depth at MaxDepth to be allowed")
+	}
+	if scope.Allow(u, 3) {
+		t.Error("expected depth beyond MaxDepth to be disallowed")
+	}
+}
+
+func TestRegexpScope(t *testing.T) {
+	scope := &RegexpScope{Pattern: regexp.MustCompile(`\.pdf$`)}
+
+	if !scope.Allow(mustParseURL(t, "http://example.com/doc.pdf"), 0) {
+		t.Error("expected matching URL to be allowed")
+	}
+	if scope.Allow(mustParseURL(t, "http://example.com/doc.html"), 0) {
+		t.Error("expected non-matching URL to be disallowed")
+	}
+}
+
+func TestSeedListScope(t *testing.T) {
+	scope := NewSeedListScope([]*url.URL{mustParseURL(t, "http://a.com/"), mustParseURL(t, "http://b.com/")})
+
+	if !scope.Allow(mustParseURL(t, "http://a.com/x"), 0) {
+		t.Error("expected seed host to be allowed")
+	}
+	if scope.Allow(mustParseURL(t, "http://c.com/x"), 0) {
+		t.Error("expected non-seed host to be disallowed")
+	}
+}
+
+func TestAndScope(t *testing.T) {
+	scope := &AndScope{Scopes: []Scope{
+		&SameHostScope{Host: "example.com"},
+		&DepthScope{MaxDepth: 1},
+	}}
+
+	if !scope.Allow(mustParseURL(t, "http://example.com/"), 1) {
+		t.Error("expected both conditions satisfied to be allowed")
+	}
+	if scope.Allow(mustParseURL(t, "http://example.com/"), 2) {
+		t.Error("expected depth violation to be disallowed")
+	}
+	if scope.Allow(mustParseURL(t, "http://other.com/"), 1) {
+		t.Error("expected host violation to be disallowed")
+	}
+}
+
+func TestOrScope(t *testing.T) {
+	scope := &OrScope{Scopes: []Scope{
+		&SameHostScope{Host: "example.com"},
+		&SameHostScope{Host: "other.com"},
+	}}
+
+	if !scope.Allow(mustParseURL(t, "http://other.com/"), 0) {
+		t.Error("expected either condition satisfied to be allowed")
+	}
+	if scope.Allow(mustParseURL(t, "http://third.com/"), 0) {
+		t.Error("expected neither condition satisfied to be disallowed")
+	}
+}
@@ -0,0 +1,43 @@
+package sitespecific
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// cloudflarestreamExtractor recovers HLS manifest URLs embedded in a
+// cloudflarestream.com player's configuration <script> block, which
+// never appear as a plain markup attribute the generic scan would catch.
+type cloudflarestreamExtractor struct{}
+
+// CloudflareStream is a SiteExtractor for pages served from a
+// cloudflarestream.com embed.
+var CloudflareStream SiteExtractor = &cloudflarestreamExtractor{}
+
+func init() {
+	Register(CloudflareStream)
+}
+
+var manifestPattern = regexp.MustCompile(`https?://[^"'\s]*cloudflarestream\.com/[^"'\s]+\.m3u8[^"'\s]*`)
+
+func (c *cloudflarestreamExtractor) Match(target *url.URL) bool {
+	return strings.HasSuffix(strings.ToLower(target.Host), "cloudflarestream.com")
+}
+
+func (c *cloudflarestreamExtractor) Extract(doc *goquery.Document, body []byte) ([]*url.URL, []*url.URL, error) {
+	var assets []*url.URL
+
+	for _, m := range manifestPattern.FindAllString(string(body), -1) {
+		u, err := url.Parse(m)
+		if err != nil {
+			continue
+		}
+
+		assets = append(assets, u)
+	}
+
+	return nil, assets, nil
+}
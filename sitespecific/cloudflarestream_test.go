@@ -0,0 +1,54 @@
+package sitespecific
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestCloudflareStreamMatch(t *testing.T) {
+	cases := []struct {
+		host string
+		want bool
+	}{
+		{"videodelivery.cloudflarestream.com", true},
+		{"CLOUDFLARESTREAM.COM", true},
+		{"example.com", false},
+	}
+
+	for _, c := range cases {
+		target := &url.URL{Scheme: "https", Host: c.host}
+		if got := CloudflareStream.Match(target); got != c.want {
+			t.Errorf("Match(%q) = %v, want %v", c.host, got, c.want)
+		}
+	}
+}
+
+func TestCloudflareStreamExtract(t *testing.T) {
+	body := []byte(`<script>
+		var config = {manifest: "https://videodelivery.cloudflarestream.com/abc123/manifest/video.m3u8?clientBandwidthHint=5"};
+	</script>`)
+
+	_, assets, err := CloudflareStream.Extract(nil, body)
+	if err != nil {
+		t.Fatalf("Extract() error: %v", err)
+	}
+
+	if len(assets) != 1 {
+		t.Fatalf("got %d assets, want 1: %v", len(assets), assets)
+	}
+
+	want := "https://videodelivery.cloudflarestream.com/abc123/manifest/video.m3u8?clientBandwidthHint=5"
+	if assets[0].String() != want {
+		t.Errorf("got asset %s, want %s", assets[0], want)
+	}
+}
+
+func TestCloudflareStreamExtractNoManifest(t *testing.T) {
+	_, assets, err := CloudflareStream.Extract(nil, []byte(`<html></html>`))
+	if err != nil {
+		t.Fatalf("Extract() error: %v", err)
+	}
+	if assets != nil {
+		t.Errorf("got assets %v, want none", assets)
+	}
+}
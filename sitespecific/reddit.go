@@ -0,0 +1,96 @@
+package sitespecific
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// redditExtractor recovers media URLs from old.reddit.com's layout:
+// post permalinks plus the data-url and preview-image JSON attributes
+// the generic tag scan never looks at.
+type redditExtractor struct{}
+
+// Reddit is a SiteExtractor for old.reddit.com, www.reddit.com, and
+// reddit.com.
+var Reddit SiteExtractor = &redditExtractor{}
+
+func init() {
+	Register(Reddit)
+}
+
+func (r *redditExtractor) Match(target *url.URL) bool {
+	switch strings.ToLower(target.Host) {
+	case "old.reddit.com", "www.reddit.com", "reddit.com":
+		return true
+	default:
+		return false
+	}
+}
+
+func (r *redditExtractor) Extract(doc *goquery.Document, body []byte) ([]*url.URL, []*url.URL, error) {
+	var urls, assets []*url.URL
+
+	doc.Find("a.title").Each(func(i int, sel *goquery.Selection) {
+		href, ok := sel.Attr("href")
+		if !ok || href == "" {
+			return
+		}
+		if u := resolve(doc.Url, href); u != nil {
+			urls = append(urls, u)
+		}
+	})
+
+	doc.Find("[data-url]").Each(func(i int, sel *goquery.Selection) {
+		v, ok := sel.Attr("data-url")
+		if !ok || v == "" {
+			return
+		}
+		if u := resolve(doc.Url, v); u != nil {
+			assets = append(assets, u)
+		}
+	})
+
+	doc.Find("[data-preview]").Each(func(i int, sel *goquery.Selection) {
+		v, ok := sel.Attr("data-preview")
+		if !ok || v == "" {
+			return
+		}
+
+		var preview struct {
+			Images []struct {
+				Source struct {
+					URL string `json:"url"`
+				} `json:"source"`
+			} `json:"images"`
+		}
+
+		if err := json.Unmarshal([]byte(v), &preview); err != nil {
+			return
+		}
+
+		for _, img := range preview.Images {
+			if img.Source.URL == "" {
+				continue
+			}
+			if u := resolve(doc.Url, img.Source.URL); u != nil {
+				assets = append(assets, u)
+			}
+		}
+	})
+
+	return urls, assets, nil
+}
+
+// resolve parses ref and resolves it against base, returning nil on any
+// parse failure.
+func resolve(base *url.URL, ref string) *url.URL {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return nil
+	}
+
+	return base.ResolveReference(u)
+}
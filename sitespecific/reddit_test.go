@@ -0,0 +1,70 @@
+package sitespecific
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestRedditMatch(t *testing.T) {
+	cases := []struct {
+		host string
+		want bool
+	}{
+		{"old.reddit.com", true},
+		{"www.reddit.com", true},
+		{"reddit.com", true},
+		{"REDDIT.COM", true},
+		{"notreddit.com", false},
+	}
+
+	for _, c := range cases {
+		target := &url.URL{Scheme: "https", Host: c.host}
+		if got := Reddit.Match(target); got != c.want {
+			t.Errorf("Match(%q) = %v, want %v", c.host, got, c.want)
+		}
+	}
+}
+
+func TestRedditExtract(t *testing.T) {
+	base, err := url.Parse("https://old.reddit.com/r/test/")
+	if err != nil {
+		t.Fatalf("failed to parse base URL: %v", err)
+	}
+
+	html := `<html><body>
+		<a class="title" href="/r/test/comments/1/post/">a post</a>
+		<div data-url="/media/clip.mp4"></div>
+		<div data-preview="{&quot;images&quot;:[{&quot;source&quot;:{&quot;url&quot;:&quot;https://i.redd.it/full.jpg&quot;}}]}"></div>
+	</body></html>`
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("failed to parse fragment: %v", err)
+	}
+	doc.Url = base
+
+	urls, assets, err := Reddit.Extract(doc, []byte(html))
+	if err != nil {
+		t.Fatalf("Extract() error: %v", err)
+	}
+
+	if len(urls) != 1 || urls[0].String() != "https://old.reddit.com/r/test/comments/1/post/" {
+		t.Errorf("got urls %v, want the post permalink", urls)
+	}
+
+	wantAssets := map[string]bool{
+		"https://old.reddit.com/media/clip.mp4": true,
+		"https://i.redd.it/full.jpg":            true,
+	}
+	if len(assets) != len(wantAssets) {
+		t.Fatalf("got %d assets, want %d: %v", len(assets), len(wantAssets), assets)
+	}
+	for _, a := range assets {
+		if !wantAssets[a.String()] {
+			t.Errorf("unexpected asset %s", a)
+		}
+	}
+}
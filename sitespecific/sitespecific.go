@@ -0,0 +1,52 @@
+// Package sitespecific lets HttpFetcher consult per-site asset
+// extractors in addition to the generic tag-table scan, which always
+// runs; their results are merged with the generic scan's. It serves
+// sites (JS-heavy SPAs, dual-layout pages, video CDNs) that emit asset
+// URLs inside JSON blobs or data-* attributes rather than plain markup.
+package sitespecific
+
+import (
+	"net/url"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// SiteExtractor recognises a particular site and extracts URLs and
+// assets from it using logic beyond the generic tag scan.
+type SiteExtractor interface {
+	// Match reports whether this extractor applies to target.
+	Match(target *url.URL) bool
+
+	// Extract returns any additional URLs and assets found on the page.
+	Extract(doc *goquery.Document, body []byte) (urls, assets []*url.URL, err error)
+}
+
+var registry []SiteExtractor
+
+// Register adds e to the set of extractors consulted for every fetched
+// page, in registration order.
+func Register(e SiteExtractor) {
+	registry = append(registry, e)
+}
+
+// ExtractFor runs every registered extractor matching target against
+// doc, merging their results. Extractors that return an error are
+// skipped; their URLs/assets are simply omitted.
+func ExtractFor(target *url.URL, doc *goquery.Document, body []byte) (urls, assets []*url.URL, errs []error) {
+	for _, e := range registry {
+		if !e.Match(target) {
+			continue
+		}
+
+		u, a, err := e.Extract(doc, body)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		urls = append(urls, u...)
+		assets = append(assets, a...)
+	}
+
+	return urls, assets, errs
+}
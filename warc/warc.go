@@ -0,0 +1,154 @@
+// Package warc writes WARC/1.0 records (https://iipc.github.io/warc-specifications/)
+// for crawled requests and responses, suitable for replay in standard
+// archive tooling such as OpenWayback or pywb.
+package warc
+
+import (
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"code.google.com/p/go-uuid/uuid"
+)
+
+// Writer appends gzipped WARC records to a file, rotating to a new file
+// once the current one reaches maxSize bytes on disk.
+type Writer struct {
+	mu      sync.Mutex
+	path    string
+	maxSize int64
+	f       *os.File
+	written int64
+	seq     int
+}
+
+// NewWriter creates a WARC writer at path. If maxSize is greater than
+// zero, the file is rotated once it reaches that many bytes, to a name
+// with ".1", ".2", etc. inserted before path's extension (out.warc.gz
+// becomes out.warc.1.gz).
+func NewWriter(path string, maxSize int64) (*Writer, error) {
+	w := &Writer{path: path, maxSize: maxSize}
+
+	if err := w.openCurrent(); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// WriteRequest appends a WARC "request" record for the raw HTTP request
+// bytes sent to target.
+func (w *Writer) WriteRequest(target string, raw []byte) error {
+	return w.writeRecord("request", target, "application/http; msgtype=request", raw)
+}
+
+// WriteResponse appends a WARC "response" record for the raw HTTP
+// response bytes (headers and body) received from target.
+func (w *Writer) WriteResponse(target string, raw []byte) error {
+	return w.writeRecord("response", target, "application/http; msgtype=response", raw)
+}
+
+// Close closes the underlying file.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.f.Close()
+}
+
+func (w *Writer) writeRecord(warcType, target, contentType string, block []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	id := uuid.NewRandom()
+
+	header := fmt.Sprintf(
+		"WARC/1.0\r\n"+
+			"WARC-Type: %s\r\n"+
+			"WARC-Target-URI: %s\r\n"+
+			"WARC-Record-ID: <urn:uuid:%s>\r\n"+
+			"WARC-Date: %s\r\n"+
+			"Content-Type: %s\r\n"+
+			"Content-Length: %d\r\n"+
+			"\r\n",
+		warcType, target, id.String(), time.Now().UTC().Format(time.RFC3339), contentType, len(block))
+
+	// Each record is gzipped independently, so the file as a whole remains
+	// a valid concatenation of gzip streams per the WARC spec.
+	gz := gzip.NewWriter(w.f)
+
+	_, err := gz.Write([]byte(header))
+	if err != nil {
+		gz.Close()
+		return err
+	}
+
+	_, err = gz.Write(block)
+	if err != nil {
+		gz.Close()
+		return err
+	}
+
+	_, err = gz.Write([]byte("\r\n\r\n"))
+	if err != nil {
+		gz.Close()
+		return err
+	}
+
+	if err := gz.Close(); err != nil {
+		return err
+	}
+
+	// Track the compressed size actually on disk, which is what maxSize
+	// bounds.
+	info, err := w.f.Stat()
+	if err != nil {
+		return err
+	}
+	w.written = info.Size()
+
+	if w.maxSize > 0 && w.written >= w.maxSize {
+		w.seq++
+		return w.openCurrent()
+	}
+
+	return nil
+}
+
+// openCurrent closes any currently open file and opens the file for the
+// current rotation sequence number.
+func (w *Writer) openCurrent() error {
+	if w.f != nil {
+		if err := w.f.Close(); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.Create(w.currentPath())
+	if err != nil {
+		return err
+	}
+
+	w.f = f
+	w.written = 0
+
+	return nil
+}
+
+// currentPath returns the file name for the current rotation sequence
+// number: path itself for seq 0, and path with ".N" inserted before the
+// extension for later rotations.
+func (w *Writer) currentPath() string {
+	if w.seq == 0 {
+		return w.path
+	}
+
+	ext := filepath.Ext(w.path)
+	base := strings.TrimSuffix(w.path, ext)
+
+	return fmt.Sprintf("%s.%d%s", base, w.seq, ext)
+}
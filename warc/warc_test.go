@@ -0,0 +1,136 @@
+package warc
+
+import (
+	"bufio"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWriterRecordFraming(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.warc.gz")
+
+	w, err := NewWriter(path, 0)
+	if err != nil {
+		t.Fatalf("NewWriter() error: %v", err)
+	}
+
+	reqBytes := []byte("GET / HTTP/1.1\r\nHost: example.com\r\n\r\n")
+	respBytes := []byte("HTTP/1.1 200 OK\r\nContent-Type: text/html\r\n\r\n<html></html>")
+
+	if err := w.WriteRequest("http://example.com/", reqBytes); err != nil {
+		t.Fatalf("WriteRequest() error: %v", err)
+	}
+	if err := w.WriteResponse("http://example.com/", respBytes); err != nil {
+		t.Fatalf("WriteResponse() error: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+
+	records := readGzipRecords(t, path)
+	if len(records) != 2 {
+		t.Fatalf("got %d gzip-independent records, want 2", len(records))
+	}
+
+	assertRecord(t, records[0], "request", "http://example.com/", reqBytes)
+	assertRecord(t, records[1], "response", "http://example.com/", respBytes)
+}
+
+func TestWriterRotatesOnMaxSize(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.warc.gz")
+
+	// maxSize of 1 byte forces a rotation after every record.
+	w, err := NewWriter(path, 1)
+	if err != nil {
+		t.Fatalf("NewWriter() error: %v", err)
+	}
+
+	if err := w.WriteRequest("http://example.com/", []byte("a")); err != nil {
+		t.Fatalf("WriteRequest() error: %v", err)
+	}
+	if err := w.WriteRequest("http://example.com/two", []byte("b")); err != nil {
+		t.Fatalf("WriteRequest() error: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("expected %s to exist: %v", path, err)
+	}
+	rotated := filepath.Join(dir, "out.warc.1.gz")
+	if _, err := os.Stat(rotated); err != nil {
+		t.Errorf("expected rotated file %s to exist: %v", rotated, err)
+	}
+}
+
+// readGzipRecords decompresses each concatenated gzip member in path
+// independently (as replay tooling would), returning each member's raw
+// decompressed content.
+func readGzipRecords(t *testing.T, path string) []string {
+	t.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", path, err)
+	}
+	defer f.Close()
+
+	var records []string
+	r := bufio.NewReader(f)
+
+	for {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			break
+		}
+		gz.Multistream(false)
+
+		var sb strings.Builder
+		buf := make([]byte, 4096)
+		for {
+			n, rerr := gz.Read(buf)
+			sb.Write(buf[:n])
+			if rerr != nil {
+				break
+			}
+		}
+		gz.Close()
+
+		records = append(records, sb.String())
+	}
+
+	return records
+}
+
+func assertRecord(t *testing.T, record, warcType, target string, block []byte) {
+	t.Helper()
+
+	if !strings.HasPrefix(record, "WARC/1.0\r\n") {
+		t.Errorf("record does not start with a WARC/1.0 header line: %q", record)
+	}
+	if !strings.Contains(record, "WARC-Type: "+warcType+"\r\n") {
+		t.Errorf("record missing WARC-Type: %s", warcType)
+	}
+	if !strings.Contains(record, "WARC-Target-URI: "+target+"\r\n") {
+		t.Errorf("record missing WARC-Target-URI: %s", target)
+	}
+	if !strings.Contains(record, "WARC-Record-ID: <urn:uuid:") {
+		t.Errorf("record missing a WARC-Record-ID")
+	}
+	if !strings.Contains(record, "Content-Length: "+strconv.Itoa(len(block))+"\r\n") {
+		t.Errorf("record missing correct Content-Length for a %d byte block", len(block))
+	}
+	if !strings.Contains(record, string(block)) {
+		t.Errorf("record does not contain the expected block")
+	}
+	if !strings.HasSuffix(record, "\r\n\r\n") {
+		t.Errorf("record does not end with the CRLF-CRLF block terminator")
+	}
+}